@@ -0,0 +1,17 @@
+//go:build !windows
+
+package shellout
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// terminate returns a Cancel function for proc that asks the process to exit gracefully by
+// sending SIGTERM rather than immediately killing it, giving it a chance to shut down within
+// the Cmd's GracePeriod.
+func terminate(proc *exec.Cmd) func() error {
+	return func() error {
+		return proc.Process.Signal(syscall.SIGTERM)
+	}
+}
@@ -0,0 +1,17 @@
+//go:build windows
+
+package shellout
+
+import (
+	"os/exec"
+)
+
+// terminate returns a Cancel function for proc that asks the process to exit gracefully. Windows
+// has no SIGTERM equivalent that arbitrary processes can handle, so this calls TerminateProcess
+// via Process.Kill; the GracePeriod still bounds how long Wait will wait for the process and its
+// I/O to finish before shellout gives up on it.
+func terminate(proc *exec.Cmd) func() error {
+	return func() error {
+		return proc.Process.Kill()
+	}
+}
@@ -0,0 +1,49 @@
+package shellout
+
+import "strings"
+
+// DedupEnv removes duplicate environment variables from env, an "os/exec"-style list of
+// "key=value" strings, keeping the value of the last occurrence of each key at that last
+// occurrence's position among the survivors. If caseInsensitive is true, keys are compared
+// case-insensitively, matching how Windows treats environment variable names. This mirrors the
+// dedup behavior the Go standard library added to os/exec for issues #49886 and #52436.
+//
+// Non-empty entries with no "=" are preserved unchanged; empty entries are dropped. Entries beginning with "=", such as Windows'
+// per-drive working directory variables ("=C:=C:\\foo"), are keyed on everything up to their
+// second "=" so that, e.g., "=C:" and "=D:" dedup independently of one another rather than
+// being collapsed together.
+func DedupEnv(env []string, caseInsensitive bool) []string {
+	// Walk env in reverse so the first occurrence of a key we encounter is that key's last
+	// occurrence in env, then reverse the result to restore the surviving entries' relative
+	// order.
+	out := make([]string, 0, len(env))
+	seen := make(map[string]bool, len(env))
+	for n := len(env); n > 0; n-- {
+		kv := env[n-1]
+		eq := strings.Index(kv, "=")
+		if eq == 0 {
+			if next := strings.Index(kv[1:], "="); next >= 0 {
+				eq = next + 1
+			}
+		}
+		if eq < 0 {
+			if kv != "" {
+				out = append(out, kv)
+			}
+			continue
+		}
+		key := kv[:eq]
+		if caseInsensitive {
+			key = strings.ToLower(key)
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, kv)
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out
+}
@@ -0,0 +1,50 @@
+package shellout
+
+import "testing"
+
+func TestDedupEnv(t *testing.T) {
+
+	t.Run("Later entries win for duplicate keys, at the later entry's position", func(t *testing.T) {
+		actual := DedupEnv([]string{"A=1", "B=2", "A=3"}, false)
+		expected := []string{"B=2", "A=3"}
+		assertEnvEqual(t, expected, actual)
+	})
+
+	t.Run("Case-insensitive comparison merges differently-cased keys", func(t *testing.T) {
+		actual := DedupEnv([]string{"Path=1", "PATH=2"}, true)
+		expected := []string{"PATH=2"}
+		assertEnvEqual(t, expected, actual)
+	})
+
+	t.Run("Case-sensitive comparison keeps differently-cased keys distinct", func(t *testing.T) {
+		actual := DedupEnv([]string{"Path=1", "PATH=2"}, false)
+		expected := []string{"Path=1", "PATH=2"}
+		assertEnvEqual(t, expected, actual)
+	})
+
+	t.Run("Different leading-= drive variables are not collapsed together", func(t *testing.T) {
+		actual := DedupEnv([]string{"=C:=C:\\foo", "=D:=D:\\bar"}, true)
+		expected := []string{"=C:=C:\\foo", "=D:=D:\\bar"}
+		assertEnvEqual(t, expected, actual)
+	})
+
+	t.Run("Duplicate leading-= drive variables still dedup like any other key", func(t *testing.T) {
+		actual := DedupEnv([]string{"=C:=C:\\foo", "=C:=C:\\bar"}, true)
+		expected := []string{"=C:=C:\\bar"}
+		assertEnvEqual(t, expected, actual)
+	})
+}
+
+func assertEnvEqual(t *testing.T, expected, actual []string) {
+	t.Helper()
+	if len(expected) != len(actual) {
+		t.Errorf("expected %v; got %v\n", expected, actual)
+		return
+	}
+	for i := range expected {
+		if expected[i] != actual[i] {
+			t.Errorf("expected %v; got %v\n", expected, actual)
+			return
+		}
+	}
+}
@@ -0,0 +1,36 @@
+//go:build !windows
+
+package shellout
+
+import (
+	"os"
+	"syscall"
+)
+
+// x_OK mirrors the POSIX X_OK bit passed to access(2); the public syscall package doesn't export
+// a named constant for it.
+const x_OK = 0x1
+
+// isExecutable returns nil if path names a regular file the current user is permitted to execute,
+// matching the access check exec.LookPath performs rather than just inspecting the file's
+// permission bits, which can diverge from what the running user is actually allowed to do (e.g. a
+// file with only the group or other execute bit set when the user isn't in that group).
+func isExecutable(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return os.ErrPermission
+	}
+	err = syscall.Access(path, x_OK)
+	// ENOSYS means Access isn't implemented; EPERM can be returned by seccomp-sandboxed
+	// containers. In both cases, fall back to checking the permission bits.
+	if err == nil || (err != syscall.ENOSYS && err != syscall.EPERM) {
+		return err
+	}
+	if info.Mode()&0111 != 0 {
+		return nil
+	}
+	return os.ErrPermission
+}
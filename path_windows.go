@@ -0,0 +1,18 @@
+//go:build windows
+
+package shellout
+
+import "os"
+
+// isExecutable returns nil if path names a regular file. Unlike exec.LookPath it does not try
+// PATHEXT extensions; callers on Windows are expected to name the file including its extension.
+func isExecutable(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return os.ErrPermission
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package shellout
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// A Resolver resolves the filesystem path to the executable for a command name, playing the
+// same role exec.LookPath plays for the standard library's exec.Command.
+type Resolver interface {
+
+	// LookPath returns the path to the executable named by name. If name contains a path
+	// separator it is checked directly and path is not consulted; otherwise each directory
+	// named in path (an os-specific, PATH-style list) is searched in order, as with
+	// exec.LookPath.
+	LookPath(name, path string) (string, error)
+}
+
+// pathResolver is the default Resolver. It searches path the same way exec.LookPath searches
+// the PATH environment variable, but takes the list to search as an argument rather than always
+// reading the process's own environment, so that Cmd.PathEnv can be honored.
+type pathResolver struct {
+
+	// strictPath and allowRelative mirror Cmd.StrictPath and Cmd.AllowRelative; see their docs.
+	strictPath    bool
+	allowRelative bool
+}
+
+func (r pathResolver) LookPath(name, path string) (string, error) {
+	if strings.ContainsRune(name, os.PathSeparator) {
+		if err := isExecutable(name); err != nil {
+			return "", err
+		}
+		return name, nil
+	}
+	for _, dir := range filepath.SplitList(path) {
+		var candidate string
+		if dir == "" {
+			// An empty PATH entry means the current directory, same as exec.LookPath. Build
+			// the candidate with an explicit "./" so a bare result doesn't get reinterpreted
+			// as a PATH-relative name by callers.
+			candidate = "." + string(os.PathSeparator) + name
+			dir = "."
+		} else {
+			candidate = filepath.Join(dir, name)
+		}
+		if err := isExecutable(candidate); err != nil {
+			continue
+		}
+		if r.strictPath && !r.allowRelative && !filepath.IsAbs(dir) {
+			return "", exec.ErrDot
+		}
+		return candidate, nil
+	}
+	return "", exec.ErrNotFound
+}
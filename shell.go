@@ -2,20 +2,31 @@
 // when executing simple shell commands programmatically.
 //
 // Note that the improved ergonomics come with reduced flexibility. This package is not appropriate
-// for running processes that require dynamic interactions with stdin and stdout or commands that
-// produce large volumes of output.
+// for running processes that require dynamic interactions with stdin and stdout. Commands that
+// produce large volumes of output should set Cmd.StdoutWriter/Cmd.StderrWriter and, if the output
+// isn't bounded by the caller, Cmd.MaxOutputBytes, since Result.Stdout/Result.Stderr otherwise
+// grow to hold the command's entire output in memory.
 package shellout
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"io"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
 
 	"github.com/ttd2089/tyers"
 )
 
-// ErrCommandNotFound is returned when a requested command can not be resolved from the PATH.
+// ErrCommandNotFound is returned when a requested command can not be resolved from the PATH. It
+// is also returned uniformly for the path-resolution failure modes introduced by Cmd.Resolver,
+// Cmd.StrictPath and Cmd.PathEnv, e.g. StrictPath rejecting a command resolved from a relative
+// PATH entry.
 var ErrCommandNotFound error = errors.New("ErrCommandNotFound")
 
 // ErrCommandProcessFailed is return when there was a problem executing the command process. Note
@@ -23,6 +34,20 @@ var ErrCommandNotFound error = errors.New("ErrCommandNotFound")
 // represented as an error.
 var ErrCommandProcessFailed error = errors.New("ErrCommandProcessFailed")
 
+// ErrCommandTimedOut is returned when a command process is terminated because its Context was
+// cancelled or its Timeout elapsed. Callers can use errors.Is(err, context.DeadlineExceeded) or
+// errors.Is(err, context.Canceled) to distinguish the two cases.
+var ErrCommandTimedOut error = errors.New("ErrCommandTimedOut")
+
+// ErrOutputTooLarge is returned when a command process writes more than MaxOutputBytes to
+// stdout or stderr.
+var ErrOutputTooLarge error = errors.New("ErrOutputTooLarge")
+
+// defaultGracePeriod is used in place of a zero Cmd.GracePeriod whenever Context or Timeout is
+// in play, so that Run is bounded even if the process ignores its termination signal or has
+// descendants that keep its output pipes open after it exits.
+const defaultGracePeriod = 100 * time.Millisecond
+
 // A Cmd contains the information required to start a command process.
 //
 // Except where documentation states otherwise the semantics of each property of a Cmd are defined
@@ -37,14 +62,100 @@ type Cmd struct {
 	// Args are the arguments to pass to the process.
 	Args []string
 
-	// Env specifies the environment of the process.
+	// Env specifies the environment of the process. As with exec.Cmd, a nil Env means the
+	// process inherits the current process's entire environment; a non-nil Env replaces it.
+	// See InheritEnv and EnvOverrides for ways to build on the current process's environment
+	// without calling os.Environ() directly.
 	Env []string
 
+	// InheritEnv, if true, includes the current process's entire environment, as returned by
+	// os.Environ(), ahead of Env when building the child process's environment. Entries in Env
+	// take precedence over inherited entries with the same key; see EnvOverrides to add
+	// overrides without needing a []string. Setting InheritEnv or EnvOverrides overrides the
+	// plain Env replace-vs-inherit semantics described above.
+	InheritEnv bool
+
+	// EnvOverrides is merged on top of InheritEnv/Env when building the child process's
+	// environment, keyed by environment variable name, letting callers express "parent
+	// environment plus these overrides" without manually assembling a []string.
+	EnvOverrides map[string]string
+
 	// Dir specifies the directory to run the command in.
 	Dir string
 
 	// Stdin specifies the process's standard input.
 	Stdin io.Reader
+
+	// Context, if non-nil, governs the lifetime of the command process. If the context is
+	// cancelled or its deadline is exceeded before the process exits, the process is sent a
+	// termination signal (SIGTERM on Unix, TerminateProcess on Windows) and, if it has not
+	// exited within GracePeriod, it is forcibly killed.
+	//
+	// If Context is nil, context.Background() is used.
+	Context context.Context
+
+	// Timeout, if non-zero, bounds the total time the command process is allowed to run.
+	// Setting Timeout is equivalent to deriving Context with context.WithTimeout and is
+	// provided as a convenience for the common case where no other cancellation is needed.
+	Timeout time.Duration
+
+	// GracePeriod is the amount of time to wait, after Context is cancelled or Timeout
+	// elapses, for the process to exit in response to the termination signal, and for its
+	// output to finish being captured, before the process is killed outright and its output
+	// pipes are forced closed. If Context and Timeout are both unset, GracePeriod has no
+	// effect. A zero GracePeriod does not mean the process is killed immediately — Run instead
+	// falls back to defaultGracePeriod so it doesn't block indefinitely on a process that
+	// ignores the termination signal or leaves descendants holding its output pipes open; set
+	// GracePeriod explicitly to control this duration.
+	GracePeriod time.Duration
+
+	// StdoutWriter, if non-nil, receives a copy of everything written to stdout by the
+	// command process as it is produced, in addition to it being captured on Result.Stdout.
+	// This allows callers to stream large volumes of output to a file or logger without
+	// giving up the ergonomics of Result.
+	StdoutWriter io.Writer
+
+	// StderrWriter, if non-nil, receives a copy of everything written to stderr by the
+	// command process as it is produced, in addition to it being captured on Result.Stderr.
+	StderrWriter io.Writer
+
+	// MaxOutputBytes, if non-zero, caps the number of bytes captured on Result.Stdout and
+	// Result.Stderr independently. If the command process writes more than MaxOutputBytes to
+	// either stream, Run returns ErrOutputTooLarge. Data already written to StdoutWriter or
+	// StderrWriter before the limit was reached is not affected. When CombinedOutput is set,
+	// the cap applies once to the combined stream rather than to each stream separately.
+	MaxOutputBytes int64
+
+	// Resolver resolves Command to an executable path. If nil, the default Resolver is used,
+	// which behaves like exec.LookPath but honors StrictPath, AllowRelative and PathEnv.
+	// Custom Resolvers are responsible for applying their own policy; StrictPath and
+	// AllowRelative have no effect when Resolver is set.
+	Resolver Resolver
+
+	// StrictPath causes the default Resolver to return ErrCommandNotFound rather than resolve
+	// Command against a relative PATH entry (including the current directory), matching the
+	// exec.ErrDot behavior introduced in Go 1.19. It has no effect if Resolver is set.
+	StrictPath bool
+
+	// AllowRelative is an escape hatch that permits the default Resolver to resolve Command
+	// against a relative PATH entry even when StrictPath is set. It has no effect if Resolver
+	// is set or if StrictPath is unset.
+	AllowRelative bool
+
+	// PathEnv, if non-empty, is the PATH-style list of directories the default Resolver
+	// searches to resolve Command, instead of the current process's PATH environment
+	// variable. This matters when Env overrides PATH for the child process, since exec.Command
+	// would otherwise still resolve Command against the parent process's PATH. A custom
+	// Resolver receives the same list (PathEnv, or the current process's PATH if PathEnv is
+	// empty) as the path argument to its LookPath method.
+	PathEnv string
+
+	// CombinedOutput causes stdout and stderr to be captured into a single buffer, exposed as
+	// Result.Combined, in the order the process wrote to them, matching exec.Cmd.CombinedOutput.
+	// When CombinedOutput is set, Result.Stdout and Result.Stderr both alias Result.Combined
+	// rather than holding their own stream's output in isolation. StdoutWriter, if set, also
+	// receives the combined stream; StderrWriter is ignored.
+	CombinedOutput bool
 }
 
 // A Result represents the outcome of running a command.
@@ -58,6 +169,11 @@ type Result struct {
 
 	// Stdout contains the data written to stderr by the command process.
 	Stderr *bytes.Buffer
+
+	// Combined contains the data written to stdout and stderr by the command process,
+	// interleaved in the order it was written. It is only populated when Cmd.CombinedOutput
+	// is set.
+	Combined *bytes.Buffer
 }
 
 // Run executes the Run method on a default instance of Shell.
@@ -85,21 +201,147 @@ type shell struct{}
 
 var defaultShell shell
 
+// teeWriter writes everything it receives to buf and, if w is non-nil, to w as well, returning
+// ErrOutputTooLarge once more than max bytes have been written. A max of zero means unbounded.
+//
+// overflowed is recorded independently of the error returned from Write because, once Write
+// starts failing, exec closes the process's end of the pipe; the process then typically dies of
+// a broken-pipe signal, and exec.Cmd.Wait prefers that *exec.ExitError over the write error that
+// triggered it. Checking overflowed lets Run report ErrOutputTooLarge regardless of which error
+// proc.Run ends up surfacing.
+type teeWriter struct {
+	buf        *bytes.Buffer
+	w          io.Writer
+	max        int64
+	n          int64
+	overflowed bool
+}
+
+func (t *teeWriter) Write(p []byte) (int, error) {
+	if t.max > 0 && t.n+int64(len(p)) > t.max {
+		t.overflowed = true
+		return 0, ErrOutputTooLarge
+	}
+	if t.w != nil {
+		if _, err := t.w.Write(p); err != nil {
+			return 0, err
+		}
+	}
+	n, err := t.buf.Write(p)
+	t.n += int64(n)
+	return n, err
+}
+
+// syncWriter serializes concurrent writes to w. Run sets a single syncWriter as both proc.Stdout
+// and proc.Stderr, so every write to the shared buffer, from either stream, goes through its
+// mutex.
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
+
 func (_ shell) Run(cmd Cmd) (Result, error) {
-	proc := exec.Command(cmd.Command, cmd.Args...)
-	if proc.Err != nil {
-		return Result{}, tyers.As(ErrCommandNotFound, proc.Err)
+	ctx := cmd.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if cmd.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cmd.Timeout)
+		defer cancel()
+	}
+	resolver := cmd.Resolver
+	if resolver == nil {
+		resolver = pathResolver{strictPath: cmd.StrictPath, allowRelative: cmd.AllowRelative}
+	}
+	pathEnv := cmd.PathEnv
+	if pathEnv == "" {
+		pathEnv = os.Getenv("PATH")
+	}
+	resolvedPath, lookErr := resolver.LookPath(cmd.Command, pathEnv)
+	if lookErr != nil {
+		return Result{}, tyers.As(ErrCommandNotFound, lookErr)
+	}
+	if !filepath.IsAbs(resolvedPath) {
+		// The Resolver searched relative PATH entries against this process's own working
+		// directory, but exec runs proc.Path relative to proc.Dir once that's set. Absolutize
+		// resolvedPath now, while it's still relative to the directory the Resolver actually
+		// searched, so resolution and execution agree regardless of Cmd.Dir.
+		absPath, err := filepath.Abs(resolvedPath)
+		if err != nil {
+			return Result{}, tyers.As(ErrCommandNotFound, err)
+		}
+		resolvedPath = absPath
+	}
+
+	proc := exec.CommandContext(ctx, cmd.Command, cmd.Args...)
+	// Args[0] keeps the original Command, matching exec.Command's own convention of resolving
+	// Path via LookPath without altering the argv[0] the child process sees. Any error from
+	// exec.Command's own (unwanted) lookup attempt is discarded in favor of our resolution.
+	proc.Path = resolvedPath
+	proc.Err = nil
+	proc.Cancel = terminate(proc)
+	if cmd.Context != nil || cmd.Timeout > 0 {
+		proc.WaitDelay = cmd.GracePeriod
+		if proc.WaitDelay <= 0 {
+			// Output is always captured through a Writer rather than an *os.File, so exec always
+			// waits on copy-pipe goroutines; with WaitDelay left at zero that wait is unbounded,
+			// and a process whose descendants inherit the pipes can hold them open well past the
+			// point where the process itself was signaled. Falling back to defaultGracePeriod
+			// keeps Run bounded without requiring every caller to set GracePeriod explicitly.
+			proc.WaitDelay = defaultGracePeriod
+		}
 	}
 	result := Result{
 		Stdout: new(bytes.Buffer),
 		Stderr: new(bytes.Buffer),
 	}
 	proc.Env = cmd.Env
+	if cmd.InheritEnv || len(cmd.EnvOverrides) > 0 {
+		merged := make([]string, 0, len(cmd.Env)+len(cmd.EnvOverrides)+16)
+		if cmd.InheritEnv {
+			merged = append(merged, os.Environ()...)
+		}
+		merged = append(merged, cmd.Env...)
+		for k, v := range cmd.EnvOverrides {
+			merged = append(merged, k+"="+v)
+		}
+		proc.Env = DedupEnv(merged, runtime.GOOS == "windows")
+	}
 	proc.Dir = cmd.Dir
 	proc.Stdin = cmd.Stdin
-	proc.Stdout = result.Stdout
-	proc.Stderr = result.Stderr
+	var stdoutTee, stderrTee *teeWriter
+	if cmd.CombinedOutput {
+		result.Combined = new(bytes.Buffer)
+		result.Stdout = result.Combined
+		result.Stderr = result.Combined
+		stdoutTee = &teeWriter{buf: result.Combined, w: cmd.StdoutWriter, max: cmd.MaxOutputBytes}
+		stderrTee = stdoutTee
+		shared := &syncWriter{w: stdoutTee}
+		proc.Stdout = shared
+		proc.Stderr = shared
+	} else {
+		stdoutTee = &teeWriter{buf: result.Stdout, w: cmd.StdoutWriter, max: cmd.MaxOutputBytes}
+		stderrTee = &teeWriter{buf: result.Stderr, w: cmd.StderrWriter, max: cmd.MaxOutputBytes}
+		proc.Stdout = stdoutTee
+		proc.Stderr = stderrTee
+	}
 	err := proc.Run()
+	if err != nil && ctx.Err() != nil {
+		// Only treat this as a timeout when the process actually failed; ctx can be observed
+		// as done even when it was cancelled concurrently with a clean exit, and a successful
+		// run should never be reported as ErrCommandTimedOut.
+		return result, tyers.As(ErrCommandTimedOut, ctx.Err())
+	}
+	if stdoutTee.overflowed || stderrTee.overflowed {
+		return result, tyers.As(ErrOutputTooLarge, err)
+	}
 	var exitErr *exec.ExitError
 	if errors.As(err, &exitErr) {
 		result.ExitCode = exitErr.ExitCode()
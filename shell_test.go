@@ -2,6 +2,7 @@ package shellout
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -9,6 +10,7 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestRun(t *testing.T) {
@@ -27,10 +29,10 @@ func TestRun(t *testing.T) {
 		}
 	})
 
-	t.Run("Empty command returns ErrCommandProcessFailed", func(t *testing.T) {
+	t.Run("Empty command returns ErrCommandNotFound", func(t *testing.T) {
 		_, err := Run(Cmd{})
-		if !errors.Is(err, ErrCommandProcessFailed) {
-			t.Errorf("expected error '%v' to be an instance of %v\n", err, ErrCommandProcessFailed)
+		if !errors.Is(err, ErrCommandNotFound) {
+			t.Errorf("expected error '%v' to be an instance of %v\n", err, ErrCommandNotFound)
 		}
 	})
 
@@ -176,4 +178,268 @@ func TestRun(t *testing.T) {
 			t.Errorf("expected '%s'; got '%s'\n", expected, actual)
 		}
 	})
+
+	t.Run("Command exceeding Timeout returns ErrCommandTimedOut", func(t *testing.T) {
+		command := "/bin/sh"
+		// sh may fork sleep as a child that inherits the output pipes and keeps them open
+		// after sh itself is signaled; Run must still return shortly after Timeout rather
+		// than block for the sleep's full duration.
+		args := []string{"-c", "sleep 5"}
+		if runtime.GOOS == "windows" {
+			command = "cmd.exe"
+			args = []string{"/c", "ping", "-n", "6", "127.0.0.1"}
+		}
+		start := time.Now()
+		_, err := Run(Cmd{
+			Command: command,
+			Args:    args,
+			Timeout: 50 * time.Millisecond,
+		})
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("expected Run to return well within the 5s sleep; took %s\n", elapsed)
+		}
+		if !errors.Is(err, ErrCommandTimedOut) {
+			t.Errorf("expected error '%v' to be an instance of %v\n", err, ErrCommandTimedOut)
+		}
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected error '%v' to wrap context.DeadlineExceeded\n", err)
+		}
+	})
+
+	t.Run("Command cancelled via Context returns ErrCommandTimedOut", func(t *testing.T) {
+		command := "/bin/sh"
+		args := []string{"-c", "sleep 5"}
+		if runtime.GOOS == "windows" {
+			command = "cmd.exe"
+			args = []string{"/c", "ping", "-n", "6", "127.0.0.1"}
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			cancel()
+		}()
+		start := time.Now()
+		_, err := Run(Cmd{
+			Command: command,
+			Args:    args,
+			Context: ctx,
+		})
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("expected Run to return well within the 5s sleep; took %s\n", elapsed)
+		}
+		if !errors.Is(err, ErrCommandTimedOut) {
+			t.Errorf("expected error '%v' to be an instance of %v\n", err, ErrCommandTimedOut)
+		}
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected error '%v' to wrap context.Canceled\n", err)
+		}
+	})
+
+	t.Run("StdoutWriter receives a copy of stdout", func(t *testing.T) {
+		expected := "passed"
+		command := "/bin/sh"
+		args := []string{"-c", fmt.Sprintf("echo %s", expected)}
+		if runtime.GOOS == "windows" {
+			command = "cmd.exe"
+			args = []string{"/c", "ECHO", expected}
+		}
+		var tee bytes.Buffer
+		res, err := Run(Cmd{
+			Command:      command,
+			Args:         args,
+			StdoutWriter: &tee,
+		})
+		if err != nil {
+			t.Errorf("unexpected error: %v\n", err)
+			t.FailNow()
+		}
+		if actual := strings.TrimSpace(res.Stdout.String()); actual != expected {
+			t.Errorf("expected Result.Stdout '%s'; got '%s'\n", expected, actual)
+		}
+		if actual := strings.TrimSpace(tee.String()); actual != expected {
+			t.Errorf("expected StdoutWriter '%s'; got '%s'\n", expected, actual)
+		}
+	})
+
+	t.Run("Output exceeding MaxOutputBytes returns ErrOutputTooLarge", func(t *testing.T) {
+		command := "/bin/sh"
+		args := []string{"-c", "echo passed"}
+		if runtime.GOOS == "windows" {
+			command = "cmd.exe"
+			args = []string{"/c", "ECHO", "passed"}
+		}
+		_, err := Run(Cmd{
+			Command:        command,
+			Args:           args,
+			MaxOutputBytes: 2,
+		})
+		if !errors.Is(err, ErrOutputTooLarge) {
+			t.Errorf("expected error '%v' to be an instance of %v\n", err, ErrOutputTooLarge)
+		}
+	})
+
+	t.Run("Streaming output exceeding MaxOutputBytes still returns ErrOutputTooLarge", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("this streaming shell pipeline is exercised on Unix shells")
+		}
+		// Unlike the "echo passed" case above, the process here keeps writing well past the
+		// cap and dies from a broken pipe once Run stops reading, which previously caused the
+		// resulting *exec.ExitError to shadow ErrOutputTooLarge.
+		_, err := Run(Cmd{
+			Command:        "/bin/sh",
+			Args:           []string{"-c", "yes | head -c 1000000"},
+			MaxOutputBytes: 10,
+		})
+		if !errors.Is(err, ErrOutputTooLarge) {
+			t.Errorf("expected error '%v' to be an instance of %v\n", err, ErrOutputTooLarge)
+		}
+	})
+
+	t.Run("StrictPath rejects a command resolved from a relative PATH entry", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("StrictPath relative-entry behavior is exercised on Unix shells")
+		}
+		_, err := Run(Cmd{
+			Command:    "echo.sh",
+			PathEnv:    "testdata",
+			StrictPath: true,
+		})
+		if !errors.Is(err, ErrCommandNotFound) {
+			t.Errorf("expected error '%v' to be an instance of %v\n", err, ErrCommandNotFound)
+		}
+	})
+
+	t.Run("AllowRelative permits StrictPath to resolve from a relative PATH entry", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("AllowRelative relative-entry behavior is exercised on Unix shells")
+		}
+		res, err := Run(Cmd{
+			Command:       "echo.sh",
+			PathEnv:       "testdata",
+			StrictPath:    true,
+			AllowRelative: true,
+		})
+		if err != nil {
+			t.Errorf("unexpected error: %v\n", err)
+			t.FailNow()
+		}
+		if actual := strings.TrimSpace(res.Stdout.String()); actual != "passed" {
+			t.Errorf("expected 'passed'; got '%s'\n", actual)
+		}
+	})
+
+	t.Run("PathEnv overrides the process PATH for command resolution", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("PathEnv override is exercised on Unix shells")
+		}
+		res, err := Run(Cmd{
+			Command: "echo.sh",
+			PathEnv: "testdata",
+		})
+		if err != nil {
+			t.Errorf("unexpected error: %v\n", err)
+			t.FailNow()
+		}
+		if actual := strings.TrimSpace(res.Stdout.String()); actual != "passed" {
+			t.Errorf("expected 'passed'; got '%s'\n", actual)
+		}
+	})
+
+	t.Run("PathEnv with a relative entry resolves correctly when Dir is also set", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("PathEnv + Dir interaction is exercised on Unix shells")
+		}
+		// PathEnv is resolved relative to this process's own working directory, not Cmd.Dir, but
+		// the process then runs with Dir as its working directory. Dir here is set to a different
+		// directory than PathEnv's entry is relative to, which previously caused the resolved
+		// path to be looked up a second time relative to Dir and fail.
+		res, err := Run(Cmd{
+			Command: "echo.sh",
+			PathEnv: "testdata",
+			Dir:     "testdata",
+		})
+		if err != nil {
+			t.Errorf("unexpected error: %v\n", err)
+			t.FailNow()
+		}
+		if actual := strings.TrimSpace(res.Stdout.String()); actual != "passed" {
+			t.Errorf("expected 'passed'; got '%s'\n", actual)
+		}
+	})
+
+	t.Run("CombinedOutput merges stdout and stderr in write order", func(t *testing.T) {
+		command := "/bin/sh"
+		args := []string{"-c", "echo out1; 1>&2 echo err1; echo out2"}
+		if runtime.GOOS == "windows" {
+			command = "cmd.exe"
+			args = []string{"/c", "ECHO out1 && 1>&2 ECHO err1 && ECHO out2"}
+		}
+		res, err := Run(Cmd{
+			Command:        command,
+			Args:           args,
+			CombinedOutput: true,
+		})
+		if err != nil {
+			t.Errorf("unexpected error: %v\n", err)
+			t.FailNow()
+		}
+		expected := "out1\nerr1\nout2"
+		if actual := strings.TrimSpace(res.Combined.String()); actual != expected {
+			t.Errorf("expected '%s'; got '%s'\n", expected, actual)
+		}
+		if res.Stdout != res.Combined || res.Stderr != res.Combined {
+			t.Errorf("expected Result.Stdout and Result.Stderr to alias Result.Combined")
+		}
+	})
+
+	t.Run("InheritEnv includes the parent environment alongside Env", func(t *testing.T) {
+		if err := os.Setenv("SHELLOUT_TEST_INHERITED", "inherited"); err != nil {
+			t.Errorf("precondition failed: failed to set environment variable: %v", err)
+			t.FailNow()
+		}
+		defer os.Unsetenv("SHELLOUT_TEST_INHERITED")
+
+		command := "/bin/sh"
+		args := []string{"-c", "echo $SHELLOUT_TEST_INHERITED $EXTRA"}
+		if runtime.GOOS == "windows" {
+			command = "cmd.exe"
+			args = []string{"/c", "ECHO", "%SHELLOUT_TEST_INHERITED%", "%EXTRA%"}
+		}
+		res, err := Run(Cmd{
+			Command:    command,
+			Args:       args,
+			Env:        []string{"EXTRA=extra"},
+			InheritEnv: true,
+		})
+		if err != nil {
+			t.Errorf("unexpected error: %v\n", err)
+			t.FailNow()
+		}
+		expected := "inherited extra"
+		if actual := strings.TrimSpace(res.Stdout.String()); actual != expected {
+			t.Errorf("expected '%s'; got '%s'\n", expected, actual)
+		}
+	})
+
+	t.Run("EnvOverrides take precedence over Env for the same key", func(t *testing.T) {
+		command := "/bin/sh"
+		args := []string{"-c", "echo $RESULT"}
+		if runtime.GOOS == "windows" {
+			command = "cmd.exe"
+			args = []string{"/c", "ECHO", "%RESULT%"}
+		}
+		res, err := Run(Cmd{
+			Command:      command,
+			Args:         args,
+			Env:          []string{"RESULT=original"},
+			EnvOverrides: map[string]string{"RESULT": "overridden"},
+		})
+		if err != nil {
+			t.Errorf("unexpected error: %v\n", err)
+			t.FailNow()
+		}
+		if actual := strings.TrimSpace(res.Stdout.String()); actual != "overridden" {
+			t.Errorf("expected 'overridden'; got '%s'\n", actual)
+		}
+	})
 }
@@ -0,0 +1,167 @@
+// Package shelloutest provides a shellout.Shell implementation for testing code that depends on
+// shellout.Shell without executing real processes.
+package shelloutest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/ttd2089/shellout"
+)
+
+var _ shellout.Shell = (*FakeShell)(nil)
+
+// A FakeShell is a shellout.Shell that records every Cmd it's asked to Run and returns a canned
+// Result or error for each one, configured in advance with ExpectCommand.
+type FakeShell struct {
+	mu           sync.Mutex
+	calls        []shellout.Cmd
+	expectations []*Expectation
+}
+
+// NewFakeShell returns a FakeShell with no registered expectations.
+func NewFakeShell() *FakeShell {
+	return &FakeShell{}
+}
+
+// ExpectCommand registers an expectation that a command named name will be run with the given
+// args, and returns an Expectation for configuring the Result or error FakeShell.Run returns
+// when it matches. If args is omitted, a Cmd's Args are not considered when matching.
+//
+// Expectations are matched in the order they were registered; the first match wins.
+func (f *FakeShell) ExpectCommand(name string, args ...string) *Expectation {
+	e := &Expectation{name: name, args: args}
+	f.mu.Lock()
+	f.expectations = append(f.expectations, e)
+	f.mu.Unlock()
+	return e
+}
+
+// Run implements shellout.Shell. It records cmd and returns the Result or error configured on
+// the first Expectation that matches it. If no registered Expectation matches, Run returns an
+// error describing the unmatched command.
+func (f *FakeShell) Run(cmd shellout.Cmd) (shellout.Result, error) {
+	var stdin []byte
+	if cmd.Stdin != nil {
+		data, err := io.ReadAll(cmd.Stdin)
+		if err == nil {
+			stdin = data
+			cmd.Stdin = bytes.NewReader(data)
+		}
+	}
+
+	f.mu.Lock()
+	f.calls = append(f.calls, cmd)
+	var matched *Expectation
+	for _, e := range f.expectations {
+		if e.matches(cmd, stdin) {
+			matched = e
+			break
+		}
+	}
+	f.mu.Unlock()
+	if matched == nil {
+		return shellout.Result{}, fmt.Errorf(
+			"shelloutest: no expectation registered for command %q with args %v", cmd.Command, cmd.Args,
+		)
+	}
+	return matched.result, matched.err
+}
+
+// Calls returns the Cmd values passed to Run, in the order Run was called.
+func (f *FakeShell) Calls() []shellout.Cmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	calls := make([]shellout.Cmd, len(f.calls))
+	copy(calls, f.calls)
+	return calls
+}
+
+// AssertCalled fails t unless a command named name was run with the given args at least once.
+// If args is omitted, a call's Args are not considered when matching.
+func (f *FakeShell) AssertCalled(t *testing.T, name string, args ...string) {
+	t.Helper()
+	for _, call := range f.Calls() {
+		if call.Command == name && (len(args) == 0 || argsEqual(call.Args, args)) {
+			return
+		}
+	}
+	t.Errorf("expected command %q with args %v to have been called; got calls: %v", name, args, f.Calls())
+}
+
+// An Expectation configures the Result or error a FakeShell returns for commands matching its
+// name, argv and, optionally, stdin content.
+type Expectation struct {
+	name   string
+	args   []string
+	stdin  []byte
+	result shellout.Result
+	err    error
+}
+
+// WithStdin restricts the Expectation to commands whose Stdin, once fully read, matches stdin.
+func (e *Expectation) WithStdin(stdin string) *Expectation {
+	e.stdin = []byte(stdin)
+	return e
+}
+
+// ReturnsStdout configures the Expectation to return a Result whose Stdout contains s.
+func (e *Expectation) ReturnsStdout(s string) *Expectation {
+	if e.result.Stdout == nil {
+		e.result.Stdout = new(bytes.Buffer)
+	}
+	e.result.Stdout.WriteString(s)
+	return e
+}
+
+// ReturnsStderr configures the Expectation to return a Result whose Stderr contains s.
+func (e *Expectation) ReturnsStderr(s string) *Expectation {
+	if e.result.Stderr == nil {
+		e.result.Stderr = new(bytes.Buffer)
+	}
+	e.result.Stderr.WriteString(s)
+	return e
+}
+
+// ReturnsExitCode configures the Expectation to return a Result with the given ExitCode.
+func (e *Expectation) ReturnsExitCode(code int) *Expectation {
+	e.result.ExitCode = code
+	return e
+}
+
+// ReturnsError configures the Expectation to return err. Run returns err together with the
+// Result configured by the other Returns methods, if any.
+func (e *Expectation) ReturnsError(err error) *Expectation {
+	e.err = err
+	return e
+}
+
+// matches reports whether cmd, whose Stdin (if any) has already been fully read into stdin,
+// satisfies e.
+func (e *Expectation) matches(cmd shellout.Cmd, stdin []byte) bool {
+	if cmd.Command != e.name {
+		return false
+	}
+	if len(e.args) > 0 && !argsEqual(cmd.Args, e.args) {
+		return false
+	}
+	if e.stdin != nil && (cmd.Stdin == nil || !bytes.Equal(stdin, e.stdin)) {
+		return false
+	}
+	return true
+}
+
+func argsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
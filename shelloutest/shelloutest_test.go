@@ -0,0 +1,127 @@
+package shelloutest
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ttd2089/shellout"
+)
+
+func TestFakeShell(t *testing.T) {
+
+	t.Run("Run returns the Result configured on a matching expectation", func(t *testing.T) {
+		fake := NewFakeShell()
+		fake.ExpectCommand("echo", "passed").
+			ReturnsStdout("passed\n").
+			ReturnsExitCode(0)
+
+		res, err := fake.Run(shellout.Cmd{Command: "echo", Args: []string{"passed"}})
+		if err != nil {
+			t.Errorf("unexpected error: %v\n", err)
+			t.FailNow()
+		}
+		actual := strings.TrimSpace(res.Stdout.String())
+		if actual != "passed" {
+			t.Errorf("expected 'passed'; got '%s'\n", actual)
+		}
+	})
+
+	t.Run("Run returns the error configured on a matching expectation", func(t *testing.T) {
+		expected := errors.New("boom")
+		fake := NewFakeShell()
+		fake.ExpectCommand("false").ReturnsError(expected)
+
+		_, err := fake.Run(shellout.Cmd{Command: "false"})
+		if !errors.Is(err, expected) {
+			t.Errorf("expected error '%v' to be '%v'\n", err, expected)
+		}
+	})
+
+	t.Run("Run returns an error when no expectation matches", func(t *testing.T) {
+		fake := NewFakeShell()
+
+		_, err := fake.Run(shellout.Cmd{Command: "echo"})
+		if err == nil {
+			t.Errorf("expected an error for an unmatched command\n")
+		}
+	})
+
+	t.Run("Expectations with Args only match calls with the same Args", func(t *testing.T) {
+		fake := NewFakeShell()
+		fake.ExpectCommand("echo", "one").ReturnsStdout("one")
+		fake.ExpectCommand("echo", "two").ReturnsStdout("two")
+
+		res, err := fake.Run(shellout.Cmd{Command: "echo", Args: []string{"two"}})
+		if err != nil {
+			t.Errorf("unexpected error: %v\n", err)
+			t.FailNow()
+		}
+		if res.Stdout.String() != "two" {
+			t.Errorf("expected 'two'; got '%s'\n", res.Stdout.String())
+		}
+	})
+
+	t.Run("WithStdin only matches calls with the same stdin content", func(t *testing.T) {
+		fake := NewFakeShell()
+		fake.ExpectCommand("cat").WithStdin("hello").ReturnsStdout("matched")
+
+		res, err := fake.Run(shellout.Cmd{Command: "cat", Stdin: strings.NewReader("hello")})
+		if err != nil {
+			t.Errorf("unexpected error: %v\n", err)
+			t.FailNow()
+		}
+		if res.Stdout.String() != "matched" {
+			t.Errorf("expected 'matched'; got '%s'\n", res.Stdout.String())
+		}
+
+		if _, err := fake.Run(shellout.Cmd{Command: "cat", Stdin: strings.NewReader("goodbye")}); err == nil {
+			t.Errorf("expected an error for stdin that doesn't match the expectation\n")
+		}
+	})
+
+	t.Run("A non-matching stdin expectation doesn't consume Stdin for later matches", func(t *testing.T) {
+		fake := NewFakeShell()
+		fake.ExpectCommand("cat").WithStdin("nope").ReturnsStdout("wrong")
+		fake.ExpectCommand("cat").WithStdin("hello").ReturnsStdout("right")
+
+		res, err := fake.Run(shellout.Cmd{Command: "cat", Stdin: strings.NewReader("hello")})
+		if err != nil {
+			t.Errorf("unexpected error: %v\n", err)
+			t.FailNow()
+		}
+		if res.Stdout.String() != "right" {
+			t.Errorf("expected 'right'; got '%s'\n", res.Stdout.String())
+		}
+
+		calls := fake.Calls()
+		data, err := io.ReadAll(calls[len(calls)-1].Stdin)
+		if err != nil {
+			t.Errorf("unexpected error reading recorded Stdin: %v\n", err)
+			t.FailNow()
+		}
+		if string(data) != "hello" {
+			t.Errorf("expected recorded Stdin to still read 'hello'; got '%s'\n", data)
+		}
+	})
+
+	t.Run("AssertCalled fails the test when the command was not called", func(t *testing.T) {
+		fake := NewFakeShell()
+		spy := &testing.T{}
+		fake.AssertCalled(spy, "echo", "passed")
+		if !spy.Failed() {
+			t.Errorf("expected AssertCalled to fail when the command was never called\n")
+		}
+	})
+
+	t.Run("AssertCalled passes the test when the command was called", func(t *testing.T) {
+		fake := NewFakeShell()
+		fake.ExpectCommand("echo", "passed").ReturnsStdout("passed")
+		if _, err := fake.Run(shellout.Cmd{Command: "echo", Args: []string{"passed"}}); err != nil {
+			t.Errorf("unexpected error: %v\n", err)
+			t.FailNow()
+		}
+		fake.AssertCalled(t, "echo", "passed")
+	})
+}